@@ -0,0 +1,88 @@
+// Package auto contains types and helpers shared by the automatic backup
+// and restore subsystems.
+package auto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+)
+
+// ErrInvalidVersion is returned when a configuration specifies a version
+// number that the receiving package does not know how to handle.
+var ErrInvalidVersion = errors.New("invalid version")
+
+// ErrUnsupportedStorageType is returned when a configuration specifies a
+// storage "type" that is not recognized by the receiving package.
+var ErrUnsupportedStorageType = errors.New("unsupported storage type")
+
+// ErrSourceNotFound is returned by a storage backend when the requested
+// object does not exist (e.g. an HTTP 404).
+var ErrSourceNotFound = errors.New("source object not found")
+
+// ErrSourceAccessDenied is returned by a storage backend when
+// credentials are rejected, or lack permission to read the requested
+// object (e.g. an HTTP 403).
+var ErrSourceAccessDenied = errors.New("access denied for source")
+
+// IsRetryable reports whether err represents a not-found, access-denied,
+// or timeout failure from a storage backend — the set of failures a
+// multi-source restore should fall back to the next source for, rather
+// than aborting immediately.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrSourceNotFound) || errors.Is(err, ErrSourceAccessDenied) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return false
+}
+
+// Duration is a time.Duration that marshals to, and unmarshals from, JSON
+// as a Go duration string (e.g. "30s") instead of an integer number of
+// nanoseconds.
+type Duration time.Duration
+
+// String returns the string representation of d.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts
+// either a duration string ("5m") or a bare number of nanoseconds, for
+// compatibility with encoding/json's default handling of time.Duration.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case float64:
+		*d = Duration(time.Duration(value))
+		return nil
+	case string:
+		pd, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*d = Duration(pd)
+		return nil
+	default:
+		return errors.New("invalid duration")
+	}
+}
@@ -0,0 +1,191 @@
+package restore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/rqlite/rqlite/v8/auto"
+)
+
+// ErrChecksumMismatch is returned when RequireChecksum is set and a
+// downloaded snapshot's SHA-256 sum doesn't match its sibling ".sha256"
+// object.
+var ErrChecksumMismatch = errors.New("snapshot checksum mismatch")
+
+// ErrNoSources is returned when a restore is given no sources to try,
+// with ContinueOnFailure unset. When sources are tried and all fail, the
+// last source's error is returned instead.
+var ErrNoSources = errors.New("no restore source available")
+
+// ChecksumDownloader is implemented by backends that can fetch the
+// sibling "<path>.sha256" checksum object alongside their main snapshot.
+type ChecksumDownloader interface {
+	DownloadChecksum(ctx context.Context) (string, error)
+}
+
+// ResolvedSource pairs a parsed, typed sub-configuration with the
+// storage type and priority it was declared with.
+type ResolvedSource struct {
+	Type     StorageType
+	Priority int
+	Sub      interface{}
+}
+
+// ResolveSources parses cfg.Sources, or, for a single-source Config, its
+// top-level Type/Sub pair, into a priority-ordered list of sources to try
+// in turn at boot time. Higher Priority values are tried first; ties
+// preserve declaration order.
+func ResolveSources(cfg *Config) ([]ResolvedSource, error) {
+	raw := cfg.Sources
+	if len(raw) == 0 {
+		raw = []SourceConfig{{Type: cfg.Type, Sub: cfg.Sub}}
+	}
+
+	resolved := make([]ResolvedSource, len(raw))
+	for i, s := range raw {
+		sub, err := unmarshalSub(s.Type, s.Sub)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = ResolvedSource{Type: s.Type, Priority: s.Priority, Sub: sub}
+	}
+
+	sort.SliceStable(resolved, func(i, j int) bool {
+		return resolved[i].Priority > resolved[j].Priority
+	})
+	return resolved, nil
+}
+
+// OpenChain tries cfg's sources in priority order, returning a stream
+// for the first one that succeeds. A source's failure only causes a
+// fall-through to the next source when auto.IsRetryable(err) is true
+// (not-found, access-denied, or a timeout); any other error aborts the
+// chain immediately. If every source fails, OpenChain returns the last
+// error encountered, unless cfg.ContinueOnFailure is set, in which case
+// it returns (nil, nil) so the caller can boot with an empty store.
+func OpenChain(ctx context.Context, cfg *Config) (io.ReadCloser, error) {
+	sources, err := ResolveSources(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return openChain(ctx, cfg, sources)
+}
+
+// openChain is OpenChain's core loop, taking already-resolved sources so
+// it can be exercised directly in tests without going through
+// ResolveSources.
+func openChain(ctx context.Context, cfg *Config, sources []ResolvedSource) (io.ReadCloser, error) {
+	var lastErr error = ErrNoSources
+	for _, src := range sources {
+		d, ok := src.Sub.(Downloader)
+		if !ok {
+			return nil, fmt.Errorf("source type %s does not implement Downloader", src.Type)
+		}
+
+		if cfg.Mode == ModePointInTime {
+			key, err := SelectPointInTime(ctx, cfg, src.Sub)
+			if err != nil {
+				lastErr = err
+				if !auto.IsRetryable(err) {
+					break
+				}
+				continue
+			}
+			if err := setResolvedPath(src.Sub, key); err != nil {
+				return nil, err
+			}
+		}
+
+		rc, err := openSource(ctx, d, cfg.RequireChecksum, cfg.Compression, cfg.ClientEncryption)
+		if err == nil {
+			return rc, nil
+		}
+		lastErr = err
+		if !auto.IsRetryable(err) {
+			break
+		}
+	}
+
+	if cfg.ContinueOnFailure {
+		return nil, nil
+	}
+	return nil, lastErr
+}
+
+// openSource downloads a single source, optionally verifying its
+// checksum and decrypting it, and returns a decompressed reader over the
+// result.
+func openSource(ctx context.Context, d Downloader, requireChecksum bool, compression Compression, encryption *ClientEncryptionConfig) (io.ReadCloser, error) {
+	rc, err := d.Download(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = rc
+	if requireChecksum {
+		cd, ok := d.(ChecksumDownloader)
+		if !ok {
+			rc.Close()
+			return nil, fmt.Errorf("require_checksum set, but source does not support checksum retrieval")
+		}
+		sum, err := cd.DownloadChecksum(ctx)
+		if err != nil {
+			rc.Close()
+			return nil, fmt.Errorf("failed to fetch checksum: %w", err)
+		}
+		r = newChecksumReader(r, sum)
+	}
+
+	if encryption != nil {
+		dr, err := decrypt(ctx, r, encryption)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		r = dr
+	}
+
+	dr, err := decompress(r, compression)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return &readCloser{Reader: dr, closer: rc}, nil
+}
+
+// checksumReader hashes the bytes read through it and, once the
+// underlying reader is exhausted, compares the result against an
+// expected hex-encoded SHA-256 sum, surfacing any mismatch as the error
+// of the final Read instead of io.EOF.
+type checksumReader struct {
+	r        io.Reader
+	hash     hash.Hash
+	expected string
+}
+
+func newChecksumReader(r io.Reader, expectedHex string) *checksumReader {
+	h := sha256.New()
+	return &checksumReader{
+		r:        io.TeeReader(r, h),
+		hash:     h,
+		expected: strings.TrimSpace(expectedHex),
+	}
+}
+
+func (cr *checksumReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	if err == io.EOF {
+		got := hex.EncodeToString(cr.hash.Sum(nil))
+		if !strings.EqualFold(got, cr.expected) {
+			return n, fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, got, cr.expected)
+		}
+	}
+	return n, err
+}
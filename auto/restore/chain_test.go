@@ -0,0 +1,225 @@
+package restore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rqlite/rqlite/v8/auto"
+)
+
+// fakeDownloader is a Downloader (and optionally ChecksumDownloader) for
+// tests that doesn't need real network access.
+type fakeDownloader struct {
+	data     string
+	checksum string
+	err      error
+}
+
+func (f *fakeDownloader) Download(ctx context.Context) (io.ReadCloser, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return io.NopCloser(strings.NewReader(f.data)), nil
+}
+
+func (f *fakeDownloader) DownloadChecksum(ctx context.Context) (string, error) {
+	return f.checksum, nil
+}
+
+// fakePointInTimeSource is a Downloader, Lister, Prefixer, and PathSetter
+// that serves whichever object in objects its resolved Path names, for
+// exercising point_in_time mode through OpenChain without real network
+// access.
+type fakePointInTimeSource struct {
+	prefix  string
+	path    string
+	objects map[string]string
+	keys    []string
+}
+
+func (f *fakePointInTimeSource) Prefix() string {
+	return f.prefix
+}
+
+func (f *fakePointInTimeSource) SetPath(key string) {
+	f.path = key
+}
+
+func (f *fakePointInTimeSource) List(ctx context.Context, prefix string) ([]string, error) {
+	return f.keys, nil
+}
+
+func (f *fakePointInTimeSource) Download(ctx context.Context) (io.ReadCloser, error) {
+	data, ok := f.objects[f.path]
+	if !ok {
+		return nil, auto.ErrSourceNotFound
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+
+func Test_OpenChain_PointInTime(t *testing.T) {
+	t.Run("selects and downloads the resolved snapshot", func(t *testing.T) {
+		src := &fakePointInTimeSource{
+			prefix: "backups/",
+			keys: []string{
+				"backups/snapshot-2026-01-01T00:00:00Z.db",
+				"backups/snapshot-2026-02-01T00:00:00Z.db",
+			},
+			objects: map[string]string{
+				"backups/snapshot-2026-01-01T00:00:00Z.db": "old-snapshot",
+				"backups/snapshot-2026-02-01T00:00:00Z.db": "new-snapshot",
+			},
+		}
+		cfg := &Config{
+			Mode:        ModePointInTime,
+			KeyPattern:  "backups/snapshot-{ts}.db",
+			AsOf:        "2026-01-15T00:00:00Z",
+			Compression: CompressionNone,
+			Type:        StorageTypeS3,
+		}
+
+		rc, err := openChain(context.Background(), cfg, []ResolvedSource{{Type: cfg.Type, Sub: src}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer rc.Close()
+
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "old-snapshot" {
+			t.Fatalf("expected old-snapshot (the snapshot at or before as_of), got %q", b)
+		}
+		if src.path != "backups/snapshot-2026-01-01T00:00:00Z.db" {
+			t.Fatalf("expected Path to be rewritten to the resolved key, got %q", src.path)
+		}
+	})
+
+	t.Run("no matching snapshot is an error", func(t *testing.T) {
+		src := &fakePointInTimeSource{
+			prefix:  "backups/",
+			keys:    []string{"backups/snapshot-2026-02-01T00:00:00Z.db"},
+			objects: map[string]string{"backups/snapshot-2026-02-01T00:00:00Z.db": "new-snapshot"},
+		}
+		cfg := &Config{
+			Mode:        ModePointInTime,
+			KeyPattern:  "backups/snapshot-{ts}.db",
+			AsOf:        "2025-01-01T00:00:00Z",
+			Compression: CompressionNone,
+			Type:        StorageTypeS3,
+		}
+
+		if _, err := openChain(context.Background(), cfg, []ResolvedSource{{Type: cfg.Type, Sub: src}}); !errors.Is(err, ErrNoMatchingSnapshot) {
+			t.Fatalf("expected ErrNoMatchingSnapshot, got %v", err)
+		}
+	})
+}
+
+func Test_ResolveSources_PriorityOrder(t *testing.T) {
+	cfg := &Config{
+		Sources: []SourceConfig{
+			{Type: StorageTypeGCS, Priority: 1, Sub: []byte(`{"bucket":"b","path":"p"}`)},
+			{Type: StorageTypeS3, Priority: 10, Sub: []byte(`{"bucket":"b","path":"p"}`)},
+			{Type: StorageTypeAzure, Priority: 5, Sub: []byte(`{"container":"c","path":"p","account":"a","account_key":"k"}`)},
+		},
+	}
+
+	resolved, err := ResolveSources(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resolved) != 3 {
+		t.Fatalf("expected 3 sources, got %d", len(resolved))
+	}
+
+	wantOrder := []StorageType{StorageTypeS3, StorageTypeAzure, StorageTypeGCS}
+	for i, w := range wantOrder {
+		if resolved[i].Type != w {
+			t.Fatalf("position %d: expected type %s, got %s", i, w, resolved[i].Type)
+		}
+	}
+}
+
+func Test_ResolveSources_SingleSourceFallback(t *testing.T) {
+	cfg := &Config{
+		Type: StorageTypeS3,
+		Sub:  []byte(`{"bucket":"b","path":"p"}`),
+	}
+
+	resolved, err := ResolveSources(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resolved) != 1 || resolved[0].Type != StorageTypeS3 {
+		t.Fatalf("expected single s3 source, got %+v", resolved)
+	}
+}
+
+func Test_OpenSource_FailsOverOnNotFound(t *testing.T) {
+	notFound := &fakeDownloader{err: auto.ErrSourceNotFound}
+	ok := &fakeDownloader{data: "snapshot-bytes"}
+
+	var lastErr error
+	var chosen io.ReadCloser
+	for _, d := range []Downloader{notFound, ok} {
+		rc, err := openSource(context.Background(), d, false, CompressionNone, nil)
+		if err == nil {
+			chosen = rc
+			break
+		}
+		lastErr = err
+		if !auto.IsRetryable(err) {
+			t.Fatalf("expected a retryable error, got %v", err)
+		}
+	}
+	if chosen == nil {
+		t.Fatalf("expected a successful source, last error: %v", lastErr)
+	}
+	b, err := io.ReadAll(chosen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "snapshot-bytes" {
+		t.Fatalf("expected snapshot-bytes, got %q", b)
+	}
+}
+
+func Test_OpenSource_ChecksumMismatch(t *testing.T) {
+	d := &fakeDownloader{data: "snapshot-bytes", checksum: "0000000000000000000000000000000000000000000000000000000000000000"}
+	rc, err := openSource(context.Background(), d, true, CompressionNone, nil)
+	if err != nil {
+		t.Fatalf("unexpected error opening source: %v", err)
+	}
+	defer rc.Close()
+
+	_, err = io.ReadAll(rc)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func Test_OpenSource_ChecksumMatch(t *testing.T) {
+	const data = "snapshot-bytes"
+	sum := sha256.Sum256([]byte(data))
+
+	d := &fakeDownloader{data: data, checksum: hex.EncodeToString(sum[:])}
+	rc, err := openSource(context.Background(), d, true, CompressionNone, nil)
+	if err != nil {
+		t.Fatalf("unexpected error opening source: %v", err)
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error, checksum should have matched: %v", err)
+	}
+	if string(b) != data {
+		t.Fatalf("expected %q, got %q", data, b)
+	}
+}
@@ -0,0 +1,91 @@
+package restore
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies the codec, if any, that a restored snapshot was
+// compressed with before being uploaded.
+type Compression string
+
+// Supported compression types.
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+	CompressionAuto Compression = "auto"
+)
+
+// ErrUnsupportedCompression is returned when a configuration specifies a
+// compression type that is not recognized.
+var ErrUnsupportedCompression = errors.New("unsupported compression type")
+
+// ErrCompressionMismatch is returned when a configuration names an
+// explicit compression codec, but the downloaded object's magic bytes
+// don't match that codec.
+var ErrCompressionMismatch = errors.New("downloaded object does not match configured compression type")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// sniffCompression inspects up to the first four bytes of a stream and
+// returns the compression codec it appears to be encoded with, or
+// CompressionNone if neither magic number is present.
+func sniffCompression(magic []byte) Compression {
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return CompressionGzip
+	case bytes.HasPrefix(magic, zstdMagic):
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// decompress wraps r with the decoder for c, so the snapshot can be
+// streamed straight into the boot path without ever touching disk as a
+// temporary file. If c is CompressionAuto, the first few bytes of r are
+// sniffed to pick a decoder. If c names an explicit codec, those same
+// bytes must match it or ErrCompressionMismatch is returned.
+func decompress(r io.Reader, c Compression) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	detected := sniffCompression(magic)
+
+	switch c {
+	case "", CompressionNone:
+		return br, nil
+	case CompressionAuto:
+		c = detected
+	default:
+		if detected != c {
+			return nil, ErrCompressionMismatch
+		}
+	}
+
+	switch c {
+	case CompressionGzip:
+		return gzip.NewReader(br)
+	case CompressionZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case CompressionNone:
+		return br, nil
+	default:
+		return nil, ErrUnsupportedCompression
+	}
+}
@@ -0,0 +1,110 @@
+package restore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func Test_Decompress(t *testing.T) {
+	plain := []byte("the quick brown fox jumps over the lazy dog")
+	gz := gzipBytes(t, plain)
+
+	t.Run("none passes through unmodified", func(t *testing.T) {
+		r, err := decompress(bytes.NewReader(plain), CompressionNone)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, plain) {
+			t.Fatalf("expected %q, got %q", plain, out)
+		}
+	})
+
+	t.Run("gzip is decoded when explicitly requested", func(t *testing.T) {
+		r, err := decompress(bytes.NewReader(gz), CompressionGzip)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, plain) {
+			t.Fatalf("expected %q, got %q", plain, out)
+		}
+	})
+
+	t.Run("auto detects gzip from magic bytes", func(t *testing.T) {
+		r, err := decompress(bytes.NewReader(gz), CompressionAuto)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, plain) {
+			t.Fatalf("expected %q, got %q", plain, out)
+		}
+	})
+
+	t.Run("auto falls back to none when no magic matches", func(t *testing.T) {
+		r, err := decompress(bytes.NewReader(plain), CompressionAuto)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, plain) {
+			t.Fatalf("expected %q, got %q", plain, out)
+		}
+	})
+
+	t.Run("mismatched magic is rejected", func(t *testing.T) {
+		_, err := decompress(bytes.NewReader(plain), CompressionGzip)
+		if !errors.Is(err, ErrCompressionMismatch) {
+			t.Fatalf("expected ErrCompressionMismatch, got %v", err)
+		}
+	})
+}
+
+func Test_SniffCompression(t *testing.T) {
+	testCases := []struct {
+		name     string
+		magic    []byte
+		expected Compression
+	}{
+		{"gzip", []byte{0x1f, 0x8b, 0x08, 0x00}, CompressionGzip},
+		{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}, CompressionZstd},
+		{"neither", []byte("SQLite"), CompressionNone},
+		{"short", []byte{0x1f}, CompressionNone},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sniffCompression(tc.magic); got != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Package restore implements automatic, boot-time restoration of a node's
+// data from a remote storage location.
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rqlite/rqlite/v8/auto"
+	"github.com/rqlite/rqlite/v8/aws"
+)
+
+// DefaultTimeout is the timeout applied to a restore operation if none is
+// specified in the configuration.
+const DefaultTimeout = 30 * time.Second
+
+// StorageType is the type of storage backend a snapshot should be
+// restored from.
+type StorageType string
+
+// Supported storage types.
+const (
+	StorageTypeS3           StorageType = "s3"
+	StorageTypeGCS          StorageType = "gcs"
+	StorageTypeAzure        StorageType = "azure"
+	StorageTypeS3Compatible StorageType = "s3_compatible"
+)
+
+// Config is the configuration for restoring a node's data, at boot time,
+// from a remote storage location. A Config either describes a single
+// source directly via Type/Sub, or a priority-ordered list of sources via
+// Sources, for failover between mirrored copies of the same snapshot.
+type Config struct {
+	Version           int                     `json:"version"`
+	Type              StorageType             `json:"type"`
+	Timeout           auto.Duration           `json:"timeout,omitempty"`
+	ContinueOnFailure bool                    `json:"continue_on_failure,omitempty"`
+	Compression       Compression             `json:"compression,omitempty"`
+	RequireChecksum   bool                    `json:"require_checksum,omitempty"`
+	ClientEncryption  *ClientEncryptionConfig `json:"client_encryption,omitempty"`
+	Mode              Mode                    `json:"mode,omitempty"`
+	KeyPattern        string                  `json:"key_pattern,omitempty"`
+	AsOf              string                  `json:"as_of,omitempty"`
+	MaxGeneration     *int64                  `json:"max_generation,omitempty"`
+	Sub               json.RawMessage         `json:"sub,omitempty"`
+	Sources           []SourceConfig          `json:"sources,omitempty"`
+}
+
+// Mode selects whether a restore fetches the single object at Path, or
+// treats Path as a prefix and picks a snapshot from everything beneath
+// it at boot time.
+type Mode string
+
+// Supported modes.
+const (
+	// ModeSingle restores the single object named by Path. This is the
+	// default when Mode is unset.
+	ModeSingle Mode = "single"
+	// ModePointInTime treats Path as a prefix, lists every object
+	// beneath it, and selects the snapshot matching AsOf or
+	// MaxGeneration per KeyPattern.
+	ModePointInTime Mode = "point_in_time"
+)
+
+// SourceConfig describes a single restore source within a multi-source
+// Config. Priority is used to order sources; higher values are tried
+// first, and ties keep their declared order.
+type SourceConfig struct {
+	Type     StorageType     `json:"type"`
+	Priority int             `json:"priority,omitempty"`
+	Sub      json.RawMessage `json:"sub,omitempty"`
+}
+
+// GCSConfig is the configuration for restoring from Google Cloud Storage.
+type GCSConfig struct {
+	Bucket              string `json:"bucket"`
+	Path                string `json:"path"`
+	CredentialsJSON     string `json:"credentials_json,omitempty"`
+	CredentialsJSONPath string `json:"credentials_json_path,omitempty"`
+	ProjectID           string `json:"project_id,omitempty"`
+}
+
+// AzureConfig is the configuration for restoring from Azure Blob Storage.
+type AzureConfig struct {
+	Container  string `json:"container"`
+	Path       string `json:"path"`
+	Account    string `json:"account"`
+	AccountKey string `json:"account_key,omitempty"`
+	SASToken   string `json:"sas_token,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
+}
+
+// S3CompatibleConfig is the configuration for restoring from an
+// S3-compatible object store, such as MinIO, Ceph RGW, Backblaze B2, or
+// Cloudflare R2, where an explicit endpoint must be supplied.
+type S3CompatibleConfig struct {
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	Bucket          string `json:"bucket"`
+	Path            string `json:"path"`
+	PathStyle       bool   `json:"path_style,omitempty"`
+	DisableSSL      bool   `json:"disable_ssl,omitempty"`
+}
+
+// Downloader is implemented by every supported storage backend. It is the
+// seam the boot-time restore code uses so it doesn't need to know which
+// backend a snapshot was fetched from.
+type Downloader interface {
+	// Download fetches the configured object and returns a reader over
+	// its raw (possibly compressed) bytes. The caller is responsible for
+	// closing the returned ReadCloser.
+	Download(ctx context.Context) (io.ReadCloser, error)
+}
+
+// ReadConfigFile reads the restore configuration file at path, expanding
+// any environment variables referenced within it (e.g. $MY_SECRET).
+func ReadConfigFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(os.ExpandEnv(string(b))), nil
+}
+
+// Unmarshal parses a restore configuration, and returns the parsed Config
+// along with the typed sub-configuration for whichever storage backend it
+// specifies. The concrete type of the second return value depends on
+// cfg.Type: *aws.S3Config for "s3", *S3CompatibleConfig for
+// "s3_compatible", *GCSConfig for "gcs", and *AzureConfig for "azure".
+// When cfg.Sources is set, every source is parsed and validated, but the
+// second return value is nil; use ResolveSources to obtain them.
+func Unmarshal(data []byte) (*Config, interface{}, error) {
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, nil, err
+	}
+	if cfg.Version != 1 {
+		return nil, nil, auto.ErrInvalidVersion
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = auto.Duration(DefaultTimeout)
+	}
+	if cfg.Compression == "" {
+		cfg.Compression = CompressionNone
+	}
+	switch cfg.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd, CompressionAuto:
+	default:
+		return nil, nil, ErrUnsupportedCompression
+	}
+	if cfg.ClientEncryption != nil {
+		if err := cfg.ClientEncryption.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModeSingle
+	}
+	if err := validatePointInTime(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	if len(cfg.Sources) > 0 {
+		if _, err := ResolveSources(cfg); err != nil {
+			return nil, nil, err
+		}
+		return cfg, nil, nil
+	}
+
+	sub, err := unmarshalSub(cfg.Type, cfg.Sub)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, sub, nil
+}
+
+// unmarshalSub parses raw into the typed sub-configuration for storage
+// backend t. The concrete type of the returned value depends on t:
+// *aws.S3Config for StorageTypeS3, *S3CompatibleConfig for
+// StorageTypeS3Compatible, *GCSConfig for StorageTypeGCS, and
+// *AzureConfig for StorageTypeAzure.
+func unmarshalSub(t StorageType, raw json.RawMessage) (interface{}, error) {
+	switch t {
+	case StorageTypeS3:
+		sub := &aws.S3Config{}
+		if err := json.Unmarshal(raw, sub); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal s3 config: %w", err)
+		}
+		if err := sub.Validate(); err != nil {
+			return nil, err
+		}
+		return sub, nil
+	case StorageTypeS3Compatible:
+		sub := &S3CompatibleConfig{}
+		if err := json.Unmarshal(raw, sub); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal s3_compatible config: %w", err)
+		}
+		return sub, nil
+	case StorageTypeGCS:
+		sub := &GCSConfig{}
+		if err := json.Unmarshal(raw, sub); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal gcs config: %w", err)
+		}
+		return sub, nil
+	case StorageTypeAzure:
+		sub := &AzureConfig{}
+		if err := json.Unmarshal(raw, sub); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal azure config: %w", err)
+		}
+		return sub, nil
+	default:
+		return nil, auto.ErrUnsupportedStorageType
+	}
+}
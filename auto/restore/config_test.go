@@ -108,6 +108,7 @@ func TestUnmarshal(t *testing.T) {
 		input       []byte
 		expectedCfg *Config
 		expectedS3  *aws.S3Config
+		expectedSub interface{}
 		expectedErr error
 	}{
 		{
@@ -131,6 +132,7 @@ func TestUnmarshal(t *testing.T) {
 				Type:              "s3",
 				Timeout:           30 * auto.Duration(time.Second),
 				ContinueOnFailure: false,
+				Compression:       CompressionNone,
 			},
 			expectedS3: &aws.S3Config{
 				AccessKeyID:     "test_id",
@@ -162,6 +164,7 @@ func TestUnmarshal(t *testing.T) {
 				Type:              "s3",
 				Timeout:           auto.Duration(30 * time.Second),
 				ContinueOnFailure: true,
+				Compression:       CompressionNone,
 			},
 			expectedS3: &aws.S3Config{
 				AccessKeyID:     "test_id",
@@ -210,12 +213,366 @@ func TestUnmarshal(t *testing.T) {
 			expectedS3:  nil,
 			expectedErr: auto.ErrUnsupportedStorageType,
 		},
+		{
+			name: "ValidGCSConfig",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "gcs",
+				"sub": {
+					"bucket": "test_bucket",
+					"path": "test/path",
+					"project_id": "test_project"
+				}
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Type:              "gcs",
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionNone,
+			},
+			expectedSub: &GCSConfig{
+				Bucket:    "test_bucket",
+				Path:      "test/path",
+				ProjectID: "test_project",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "ValidAzureConfig",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "azure",
+				"sub": {
+					"container": "test_container",
+					"path": "test/path",
+					"account": "test_account",
+					"account_key": "test_key"
+				}
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Type:              "azure",
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionNone,
+			},
+			expectedSub: &AzureConfig{
+				Container:  "test_container",
+				Path:       "test/path",
+				Account:    "test_account",
+				AccountKey: "test_key",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "ValidS3CompatibleConfig",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3_compatible",
+				"sub": {
+					"endpoint": "https://minio.example.com",
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"bucket": "test_bucket",
+					"path": "test/path",
+					"path_style": true
+				}
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Type:              "s3_compatible",
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionNone,
+			},
+			expectedSub: &S3CompatibleConfig{
+				Endpoint:        "https://minio.example.com",
+				AccessKeyID:     "test_id",
+				SecretAccessKey: "test_secret",
+				Bucket:          "test_bucket",
+				Path:            "test/path",
+				PathStyle:       true,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "ValidS3ConfigWithGzipCompression",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"compression": "gzip",
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "test/path"
+				}
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Type:              "s3",
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionGzip,
+			},
+			expectedS3: &aws.S3Config{
+				AccessKeyID:     "test_id",
+				SecretAccessKey: "test_secret",
+				Region:          "us-west-2",
+				Bucket:          "test_bucket",
+				Path:            "test/path",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "ValidS3ConfigWithAutoCompression",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"compression": "auto",
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "test/path"
+				}
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Type:              "s3",
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionAuto,
+			},
+			expectedS3: &aws.S3Config{
+				AccessKeyID:     "test_id",
+				SecretAccessKey: "test_secret",
+				Region:          "us-west-2",
+				Bucket:          "test_bucket",
+				Path:            "test/path",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "UnsupportedCompression",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"compression": "bzip2",
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "test/path"
+				}
+			}
+			`),
+			expectedCfg: nil,
+			expectedS3:  nil,
+			expectedErr: ErrUnsupportedCompression,
+		},
+		{
+			name: "ValidS3ConfigWithSSEKMS",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "test/path",
+					"sse": "aws:kms",
+					"sse_kms_key_id": "arn:aws:kms:us-west-2:111122223333:key/abc"
+				}
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Type:              "s3",
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionNone,
+			},
+			expectedS3: &aws.S3Config{
+				AccessKeyID:     "test_id",
+				SecretAccessKey: "test_secret",
+				Region:          "us-west-2",
+				Bucket:          "test_bucket",
+				Path:            "test/path",
+				SSE:             aws.SSEKMS,
+				SSEKMSKeyID:     "arn:aws:kms:us-west-2:111122223333:key/abc",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "InvalidSSEConfig",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "test/path",
+					"sse": "C"
+				}
+			}
+			`),
+			expectedCfg: nil,
+			expectedS3:  nil,
+			expectedErr: aws.ErrInvalidSSEConfig,
+		},
+		{
+			name: "InvalidClientEncryptionConfig",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"client_encryption": {
+					"algorithm": "AES-256-GCM",
+					"key_source": "kms"
+				},
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "test/path"
+				}
+			}
+			`),
+			expectedCfg: nil,
+			expectedS3:  nil,
+			expectedErr: ErrInvalidEncryptionConfig,
+		},
+		{
+			name: "ValidS3ConfigWithPointInTimeAsOf",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"mode": "point_in_time",
+				"key_pattern": "backups/snapshot-{ts}.db",
+				"as_of": "2026-07-01T00:00:00Z",
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "backups/"
+				}
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Type:              "s3",
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionNone,
+			},
+			expectedS3: &aws.S3Config{
+				AccessKeyID:     "test_id",
+				SecretAccessKey: "test_secret",
+				Region:          "us-west-2",
+				Bucket:          "test_bucket",
+				Path:            "backups/",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "InvalidPointInTimeConfigMissingSelector",
+			input: []byte(`
+			{
+				"version": 1,
+				"type": "s3",
+				"mode": "point_in_time",
+				"key_pattern": "backups/snapshot-{ts}.db",
+				"sub": {
+					"access_key_id": "test_id",
+					"secret_access_key": "test_secret",
+					"region": "us-west-2",
+					"bucket": "test_bucket",
+					"path": "backups/"
+				}
+			}
+			`),
+			expectedCfg: nil,
+			expectedS3:  nil,
+			expectedErr: ErrInvalidPointInTimeConfig,
+		},
+		{
+			name: "ValidSources",
+			input: []byte(`
+			{
+				"version": 1,
+				"sources": [
+					{
+						"type": "s3",
+						"priority": 1,
+						"sub": {
+							"access_key_id": "test_id",
+							"secret_access_key": "test_secret",
+							"region": "us-west-2",
+							"bucket": "test_bucket",
+							"path": "test/path"
+						}
+					}
+				]
+			}
+			`),
+			expectedCfg: &Config{
+				Version:           1,
+				Timeout:           30 * auto.Duration(time.Second),
+				ContinueOnFailure: false,
+				Compression:       CompressionNone,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "SourcesWithUnsupportedTypeFailsEagerly",
+			input: []byte(`
+			{
+				"version": 1,
+				"sources": [
+					{
+						"type": "unsupported",
+						"priority": 1,
+						"sub": {
+							"bucket": "test_bucket",
+							"path": "test/path"
+						}
+					}
+				]
+			}
+			`),
+			expectedCfg: nil,
+			expectedS3:  nil,
+			expectedErr: auto.ErrUnsupportedStorageType,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			cfg, s3Cfg, err := Unmarshal(tc.input)
-			_ = s3Cfg
 
 			if !errors.Is(err, tc.expectedErr) {
 				t.Fatalf("Test case %s failed, expected error %v, got %v", tc.name, tc.expectedErr, err)
@@ -230,6 +587,12 @@ func TestUnmarshal(t *testing.T) {
 					t.Fatalf("Test case %s failed, expected S3Config %+v, got %+v", tc.name, tc.expectedS3, s3Cfg)
 				}
 			}
+
+			if tc.expectedSub != nil {
+				if !reflect.DeepEqual(s3Cfg, tc.expectedSub) {
+					t.Fatalf("Test case %s failed, expected sub-config %+v, got %+v", tc.name, tc.expectedSub, s3Cfg)
+				}
+			}
 		})
 	}
 }
@@ -240,5 +603,6 @@ func compareConfig(a, b *Config) bool {
 	}
 	return a.Version == b.Version &&
 		a.Type == b.Type &&
-		a.Timeout == b.Timeout
+		a.Timeout == b.Timeout &&
+		a.Compression == b.Compression
 }
@@ -0,0 +1,23 @@
+package restore
+
+import "io"
+
+// readCloser pairs a (possibly decompressing or decrypting) Reader with
+// the Closer of the underlying, raw download stream. If Reader is itself
+// an io.Closer (e.g. a zstd decoder, which must be closed to release its
+// background goroutines), Close closes it before the raw stream.
+type readCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (rc *readCloser) Close() error {
+	var err error
+	if c, ok := rc.Reader.(io.Closer); ok {
+		err = c.Close()
+	}
+	if cerr := rc.closer.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
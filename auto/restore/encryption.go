@@ -0,0 +1,160 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ClientEncryptionAlgorithm identifies the cipher a snapshot was
+// encrypted with before being uploaded.
+type ClientEncryptionAlgorithm string
+
+// ClientEncryptionAlgorithmAES256GCM is currently the only supported
+// client-side encryption algorithm.
+const ClientEncryptionAlgorithmAES256GCM ClientEncryptionAlgorithm = "AES-256-GCM"
+
+// ClientEncryptionKeySource identifies where the decryption key should
+// be read from.
+type ClientEncryptionKeySource string
+
+// Supported key sources.
+const (
+	ClientEncryptionKeySourceFile ClientEncryptionKeySource = "file"
+	ClientEncryptionKeySourceEnv  ClientEncryptionKeySource = "env"
+	ClientEncryptionKeySourceKMS  ClientEncryptionKeySource = "kms"
+)
+
+// ErrUnsupportedEncryptionAlgorithm is returned when a configuration
+// names a client-side encryption algorithm that isn't recognized.
+var ErrUnsupportedEncryptionAlgorithm = errors.New("unsupported client-side encryption algorithm")
+
+// ErrInvalidEncryptionConfig is returned when a ClientEncryptionConfig's
+// key-source fields are inconsistent, e.g. more than one key source
+// populated, or none at all.
+var ErrInvalidEncryptionConfig = errors.New("invalid client-side encryption configuration")
+
+// ErrUnsupportedKeySource is returned when a ClientEncryptionConfig names
+// a key_source that Validate recognizes but restore cannot yet retrieve
+// a key from.
+var ErrUnsupportedKeySource = errors.New("unsupported client-side encryption key_source")
+
+// ClientEncryptionConfig describes client-side encryption applied to a
+// snapshot before it was uploaded, so it can be decrypted on the way
+// back in. The ciphertext is expected to be the per-object nonce,
+// prefixed directly onto the AEAD ciphertext.
+type ClientEncryptionConfig struct {
+	Algorithm ClientEncryptionAlgorithm `json:"algorithm"`
+	KeySource ClientEncryptionKeySource `json:"key_source"`
+	KeyFile   string                    `json:"key_file,omitempty"`
+	KeyEnvVar string                    `json:"key_env_var,omitempty"`
+	KMSKeyID  string                    `json:"kms_key_id,omitempty"`
+}
+
+// Validate checks that c names a supported algorithm and exactly one
+// key source consistent with KeySource.
+func (c *ClientEncryptionConfig) Validate() error {
+	if c.Algorithm != ClientEncryptionAlgorithmAES256GCM {
+		return fmt.Errorf("%w: %q", ErrUnsupportedEncryptionAlgorithm, c.Algorithm)
+	}
+
+	set := 0
+	for _, v := range []string{c.KeyFile, c.KeyEnvVar, c.KMSKeyID} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("%w: exactly one of key_file, key_env_var, or kms_key_id must be set", ErrInvalidEncryptionConfig)
+	}
+
+	switch c.KeySource {
+	case ClientEncryptionKeySourceFile:
+		if c.KeyFile == "" {
+			return fmt.Errorf("%w: key_source \"file\" requires key_file", ErrInvalidEncryptionConfig)
+		}
+	case ClientEncryptionKeySourceEnv:
+		if c.KeyEnvVar == "" {
+			return fmt.Errorf("%w: key_source \"env\" requires key_env_var", ErrInvalidEncryptionConfig)
+		}
+	case ClientEncryptionKeySourceKMS:
+		if c.KMSKeyID == "" {
+			return fmt.Errorf("%w: key_source \"kms\" requires kms_key_id", ErrInvalidEncryptionConfig)
+		}
+		return fmt.Errorf("%w: \"kms\" is not yet supported for restore", ErrUnsupportedKeySource)
+	default:
+		return fmt.Errorf("%w: unknown key_source %q", ErrInvalidEncryptionConfig, c.KeySource)
+	}
+	return nil
+}
+
+// key resolves the raw, base64-decoded decryption key from the
+// configured source.
+func (c *ClientEncryptionConfig) key(ctx context.Context) ([]byte, error) {
+	var raw string
+	switch c.KeySource {
+	case ClientEncryptionKeySourceFile:
+		b, err := os.ReadFile(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		raw = string(b)
+	case ClientEncryptionKeySourceEnv:
+		raw = os.Getenv(c.KeyEnvVar)
+		if raw == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", c.KeyEnvVar)
+		}
+	case ClientEncryptionKeySourceKMS:
+		return nil, fmt.Errorf("%w: \"kms\"", ErrUnsupportedKeySource)
+	default:
+		return nil, fmt.Errorf("%w: unknown key_source %q", ErrInvalidEncryptionConfig, c.KeySource)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// decrypt reads all of r, treats its first bytes as an AES-GCM nonce
+// followed by the ciphertext, and returns the decrypted plaintext. AEAD
+// decryption can't begin until the whole ciphertext and its
+// authentication tag have been read, so, unlike decompress, this is not
+// a streaming operation.
+func decrypt(ctx context.Context, r io.Reader, c *ClientEncryptionConfig) (io.Reader, error) {
+	key, err := c.key(ctx)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted snapshot is shorter than the nonce size")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+	}
+	return bytes.NewReader(plain), nil
+}
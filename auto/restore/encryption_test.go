@@ -0,0 +1,173 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+)
+
+func Test_ClientEncryptionConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     ClientEncryptionConfig
+		wantErr error
+	}{
+		{
+			name: "valid file source",
+			cfg: ClientEncryptionConfig{
+				Algorithm: ClientEncryptionAlgorithmAES256GCM,
+				KeySource: ClientEncryptionKeySourceFile,
+				KeyFile:   "/etc/rqlite/restore.key",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "valid env source",
+			cfg: ClientEncryptionConfig{
+				Algorithm: ClientEncryptionAlgorithmAES256GCM,
+				KeySource: ClientEncryptionKeySourceEnv,
+				KeyEnvVar: "RQLITE_RESTORE_KEY",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "unsupported algorithm",
+			cfg: ClientEncryptionConfig{
+				Algorithm: "AES-128-CBC",
+				KeySource: ClientEncryptionKeySourceEnv,
+				KeyEnvVar: "RQLITE_RESTORE_KEY",
+			},
+			wantErr: ErrUnsupportedEncryptionAlgorithm,
+		},
+		{
+			name: "multiple key sources set",
+			cfg: ClientEncryptionConfig{
+				Algorithm: ClientEncryptionAlgorithmAES256GCM,
+				KeySource: ClientEncryptionKeySourceFile,
+				KeyFile:   "/etc/rqlite/restore.key",
+				KeyEnvVar: "RQLITE_RESTORE_KEY",
+			},
+			wantErr: ErrInvalidEncryptionConfig,
+		},
+		{
+			name: "key_source mismatched with populated field",
+			cfg: ClientEncryptionConfig{
+				Algorithm: ClientEncryptionAlgorithmAES256GCM,
+				KeySource: ClientEncryptionKeySourceKMS,
+				KeyFile:   "/etc/rqlite/restore.key",
+			},
+			wantErr: ErrInvalidEncryptionConfig,
+		},
+		{
+			name: "unknown key_source",
+			cfg: ClientEncryptionConfig{
+				Algorithm: ClientEncryptionAlgorithmAES256GCM,
+				KeySource: "vault",
+				KeyFile:   "/etc/rqlite/restore.key",
+			},
+			wantErr: ErrInvalidEncryptionConfig,
+		},
+		{
+			name: "kms key_source is not yet supported",
+			cfg: ClientEncryptionConfig{
+				Algorithm: ClientEncryptionAlgorithmAES256GCM,
+				KeySource: ClientEncryptionKeySourceKMS,
+				KMSKeyID:  "arn:aws:kms:us-west-2:111122223333:key/abc",
+			},
+			wantErr: ErrUnsupportedKeySource,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_Decrypt(t *testing.T) {
+	plain := []byte("the quick brown fox jumps over the lazy dog")
+
+	rawKey := make([]byte, 32)
+	if _, err := rand.Read(rawKey); err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(rawKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := append(nonce, gcm.Seal(nil, nonce, plain, nil)...)
+
+	t.Setenv("TEST_RESTORE_KEY", base64.StdEncoding.EncodeToString(rawKey))
+	cfg := &ClientEncryptionConfig{
+		Algorithm: ClientEncryptionAlgorithmAES256GCM,
+		KeySource: ClientEncryptionKeySourceEnv,
+		KeyEnvVar: "TEST_RESTORE_KEY",
+	}
+
+	r, err := decrypt(context.Background(), bytes.NewReader(ciphertext), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Fatalf("expected %q, got %q", plain, got)
+	}
+}
+
+func Test_Decrypt_WrongKeyFails(t *testing.T) {
+	plain := []byte("the quick brown fox jumps over the lazy dog")
+
+	rawKey := make([]byte, 32)
+	if _, err := rand.Read(rawKey); err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(rawKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := append(nonce, gcm.Seal(nil, nonce, plain, nil)...)
+
+	wrongKey := make([]byte, 32)
+	if _, err := rand.Read(wrongKey); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_RESTORE_KEY_WRONG", base64.StdEncoding.EncodeToString(wrongKey))
+	cfg := &ClientEncryptionConfig{
+		Algorithm: ClientEncryptionAlgorithmAES256GCM,
+		KeySource: ClientEncryptionKeySourceEnv,
+		KeyEnvVar: "TEST_RESTORE_KEY_WRONG",
+	}
+
+	if _, err := decrypt(context.Background(), bytes.NewReader(ciphertext), cfg); err == nil {
+		t.Fatal("expected an error decrypting with the wrong key")
+	}
+}
@@ -0,0 +1,125 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/rqlite/rqlite/v8/auto"
+)
+
+// Download fetches the configured object from Google Cloud Storage and
+// returns a reader over its raw bytes.
+func (c *GCSConfig) Download(ctx context.Context) (io.ReadCloser, error) {
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := client.Bucket(c.Bucket).Object(c.Path).NewReader(ctx)
+	if err != nil {
+		client.Close()
+		return nil, classifyGCSError(err)
+	}
+	return &gcsObjectReader{ReadCloser: rc, client: client}, nil
+}
+
+// DownloadChecksum fetches the sibling "<path>.sha256" object and
+// returns its contents as a trimmed string.
+func (c *GCSConfig) DownloadChecksum(ctx context.Context) (string, error) {
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	rc, err := client.Bucket(c.Bucket).Object(c.Path + ".sha256").NewReader(ctx)
+	if err != nil {
+		return "", classifyGCSError(err)
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// List returns the keys of every object in the bucket beginning with
+// prefix.
+func (c *GCSConfig) List(ctx context.Context, prefix string) ([]string, error) {
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	var keys []string
+	it := client.Bucket(c.Bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, classifyGCSError(err)
+		}
+		keys = append(keys, attrs.Name)
+	}
+	return keys, nil
+}
+
+// Prefix returns c.Path, the prefix under which point_in_time mode lists
+// candidate snapshots.
+func (c *GCSConfig) Prefix() string {
+	return c.Path
+}
+
+// SetPath rewrites c.Path to key, the full object key a point_in_time
+// restore resolved from the configured prefix.
+func (c *GCSConfig) SetPath(key string) {
+	c.Path = key
+}
+
+func (c *GCSConfig) newClient(ctx context.Context) (*storage.Client, error) {
+	var opts []option.ClientOption
+	switch {
+	case c.CredentialsJSON != "":
+		opts = append(opts, option.WithCredentialsJSON([]byte(c.CredentialsJSON)))
+	case c.CredentialsJSONPath != "":
+		opts = append(opts, option.WithCredentialsFile(c.CredentialsJSONPath))
+	}
+	return storage.NewClient(ctx, opts...)
+}
+
+// classifyGCSError maps a Cloud Storage error to auto.ErrSourceNotFound
+// where applicable, so a multi-source restore knows when it's safe to
+// fall back to the next configured source.
+func classifyGCSError(err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("%w: %s", auto.ErrSourceNotFound, err)
+	}
+	return err
+}
+
+// gcsObjectReader closes both the object reader and the client that
+// created it once the caller is done with the download.
+type gcsObjectReader struct {
+	io.ReadCloser
+	client *storage.Client
+}
+
+func (r *gcsObjectReader) Close() error {
+	err := r.ReadCloser.Close()
+	if cerr := r.client.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
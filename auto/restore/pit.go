@@ -0,0 +1,254 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPointInTimeConfig is returned when a Config's point-in-time
+// fields (mode, key_pattern, as_of, max_generation) are missing or
+// inconsistent.
+var ErrInvalidPointInTimeConfig = errors.New("invalid point-in-time configuration")
+
+// ErrNoMatchingSnapshot is returned when a point-in-time restore's
+// selection criteria (as_of or max_generation) match none of the
+// objects found under the configured prefix.
+var ErrNoMatchingSnapshot = errors.New("no matching snapshot found")
+
+// Lister is implemented by storage backends that can enumerate objects
+// under a prefix, a prerequisite for point_in_time mode.
+type Lister interface {
+	// List returns the full keys of every object stored under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Prefixer is implemented by storage backends whose configured Path is
+// used as a listing prefix in point_in_time mode.
+type Prefixer interface {
+	// Prefix returns the configured path under which candidate snapshots
+	// are listed.
+	Prefix() string
+}
+
+// PathSetter is implemented by storage backends that support rewriting
+// their configured object key once point_in_time mode has resolved a
+// prefix listing to an actual snapshot, so the following Download
+// fetches that snapshot rather than the raw prefix.
+type PathSetter interface {
+	SetPath(key string)
+}
+
+// keyPatternPlaceholder matches the {ts} and {gen} placeholders a
+// key_pattern uses to mark where a timestamp or generation counter
+// appears in an object key.
+var keyPatternPlaceholder = regexp.MustCompile(`\{(ts|gen)\}`)
+
+// compileKeyPattern turns a key_pattern such as
+// "backups/snapshot-{ts}.db" into a regular expression with named
+// capture groups "ts" and/or "gen", anchored to match a full key.
+// Every character outside a placeholder is matched literally.
+func compileKeyPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("%w: key_pattern must not be empty", ErrInvalidPointInTimeConfig)
+	}
+
+	var b strings.Builder
+	b.WriteByte('^')
+	last := 0
+	for _, loc := range keyPatternPlaceholder.FindAllStringIndex(pattern, -1) {
+		b.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		switch pattern[loc[0]+1 : loc[1]-1] {
+		case "ts":
+			b.WriteString(`(?P<ts>[0-9TZ:.+-]+)`)
+		case "gen":
+			b.WriteString(`(?P<gen>\d+)`)
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(pattern[last:]))
+	b.WriteByte('$')
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPointInTimeConfig, err)
+	}
+	if re.SubexpIndex("ts") < 0 && re.SubexpIndex("gen") < 0 {
+		return nil, fmt.Errorf("%w: key_pattern must contain {ts} or {gen}", ErrInvalidPointInTimeConfig)
+	}
+	return re, nil
+}
+
+// validatePointInTime checks that cfg's point-in-time fields are
+// consistent for its Mode. It is a no-op for ModeSingle.
+func validatePointInTime(cfg *Config) error {
+	switch cfg.Mode {
+	case ModeSingle:
+		return nil
+	case ModePointInTime:
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidPointInTimeConfig, cfg.Mode)
+	}
+
+	if (cfg.AsOf == "") == (cfg.MaxGeneration == nil) {
+		return fmt.Errorf("%w: point_in_time mode requires exactly one of as_of or max_generation", ErrInvalidPointInTimeConfig)
+	}
+	if cfg.AsOf != "" {
+		if _, err := time.Parse(time.RFC3339, cfg.AsOf); err != nil {
+			return fmt.Errorf("%w: invalid as_of: %v", ErrInvalidPointInTimeConfig, err)
+		}
+	}
+	if _, err := compileKeyPattern(cfg.KeyPattern); err != nil {
+		return err
+	}
+	return nil
+}
+
+// snapshotKey pairs an object key with the timestamp and/or generation
+// extracted from it via a key_pattern.
+type snapshotKey struct {
+	key    string
+	ts     time.Time
+	hasTS  bool
+	gen    int64
+	hasGen bool
+}
+
+// parseSnapshotKeys matches each of keys against pattern, extracting its
+// ts and/or gen capture groups. Keys that don't match pattern are
+// silently skipped, since a prefix listing can include unrelated
+// objects.
+func parseSnapshotKeys(pattern *regexp.Regexp, keys []string) ([]snapshotKey, error) {
+	tsIdx := pattern.SubexpIndex("ts")
+	genIdx := pattern.SubexpIndex("gen")
+
+	parsed := make([]snapshotKey, 0, len(keys))
+	for _, k := range keys {
+		m := pattern.FindStringSubmatch(k)
+		if m == nil {
+			continue
+		}
+
+		sk := snapshotKey{key: k}
+		if tsIdx >= 0 && m[tsIdx] != "" {
+			ts, err := time.Parse(time.RFC3339, m[tsIdx])
+			if err != nil {
+				return nil, fmt.Errorf("key %q: failed to parse timestamp %q: %w", k, m[tsIdx], err)
+			}
+			sk.ts, sk.hasTS = ts, true
+		}
+		if genIdx >= 0 && m[genIdx] != "" {
+			gen, err := strconv.ParseInt(m[genIdx], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: failed to parse generation %q: %w", k, m[genIdx], err)
+			}
+			sk.gen, sk.hasGen = gen, true
+		}
+		parsed = append(parsed, sk)
+	}
+	return parsed, nil
+}
+
+// selectByAsOf returns the key of the newest snapshot at or before asOf.
+func selectByAsOf(keys []snapshotKey, asOf time.Time) (string, error) {
+	var best *snapshotKey
+	for i := range keys {
+		k := &keys[i]
+		if !k.hasTS || k.ts.After(asOf) {
+			continue
+		}
+		if best == nil || k.ts.After(best.ts) {
+			best = k
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("%w: none at or before %s", ErrNoMatchingSnapshot, asOf.Format(time.RFC3339))
+	}
+	return best.key, nil
+}
+
+// selectByGeneration returns the key of the highest-generation snapshot
+// at or below maxGeneration.
+func selectByGeneration(keys []snapshotKey, maxGeneration int64) (string, error) {
+	var best *snapshotKey
+	for i := range keys {
+		k := &keys[i]
+		if !k.hasGen || k.gen > maxGeneration {
+			continue
+		}
+		if best == nil || k.gen > best.gen {
+			best = k
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("%w: none with generation <= %d", ErrNoMatchingSnapshot, maxGeneration)
+	}
+	return best.key, nil
+}
+
+// pathPrefix returns the configured Path of a sub-configuration, used as
+// the listing prefix in point_in_time mode.
+func pathPrefix(sub interface{}) (string, bool) {
+	p, ok := sub.(Prefixer)
+	if !ok {
+		return "", false
+	}
+	return p.Prefix(), true
+}
+
+// setResolvedPath rewrites sub's configured Path to key, the full object
+// key SelectPointInTime resolved from the configured prefix, so the
+// subsequent Download fetches the selected snapshot instead of the raw
+// prefix.
+func setResolvedPath(sub interface{}, key string) error {
+	ps, ok := sub.(PathSetter)
+	if !ok {
+		return fmt.Errorf("source does not support point_in_time mode")
+	}
+	ps.SetPath(key)
+	return nil
+}
+
+// SelectPointInTime lists the objects stored under sub's configured
+// Path, then returns the full key of whichever matches cfg's AsOf or
+// MaxGeneration selection criteria per cfg.KeyPattern. KeyPattern is
+// matched against the full key as returned by List, not a path relative
+// to Path, so it must include Path's own literal prefix.
+func SelectPointInTime(ctx context.Context, cfg *Config, sub interface{}) (string, error) {
+	prefix, ok := pathPrefix(sub)
+	if !ok {
+		return "", fmt.Errorf("source does not support point_in_time mode")
+	}
+	lister, ok := sub.(Lister)
+	if !ok {
+		return "", fmt.Errorf("source does not support point_in_time mode")
+	}
+
+	pattern, err := compileKeyPattern(cfg.KeyPattern)
+	if err != nil {
+		return "", err
+	}
+
+	keys, err := lister.List(ctx, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := parseSnapshotKeys(pattern, keys)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.AsOf != "" {
+		asOf, err := time.Parse(time.RFC3339, cfg.AsOf)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid as_of: %v", ErrInvalidPointInTimeConfig, err)
+		}
+		return selectByAsOf(parsed, asOf)
+	}
+	return selectByGeneration(parsed, *cfg.MaxGeneration)
+}
@@ -0,0 +1,251 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeLister struct {
+	prefix string
+	keys   []string
+	err    error
+}
+
+func (f *fakeLister) Prefix() string {
+	return f.prefix
+}
+
+func (f *fakeLister) List(ctx context.Context, prefix string) ([]string, error) {
+	return f.keys, f.err
+}
+
+func Test_CompileKeyPattern(t *testing.T) {
+	t.Run("empty pattern is rejected", func(t *testing.T) {
+		if _, err := compileKeyPattern(""); !errors.Is(err, ErrInvalidPointInTimeConfig) {
+			t.Fatalf("expected ErrInvalidPointInTimeConfig, got %v", err)
+		}
+	})
+
+	t.Run("pattern without placeholders is rejected", func(t *testing.T) {
+		if _, err := compileKeyPattern("backups/snapshot.db"); !errors.Is(err, ErrInvalidPointInTimeConfig) {
+			t.Fatalf("expected ErrInvalidPointInTimeConfig, got %v", err)
+		}
+	})
+
+	t.Run("ts placeholder matches and captures", func(t *testing.T) {
+		re, err := compileKeyPattern("backups/snapshot-{ts}.db")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		m := re.FindStringSubmatch("backups/snapshot-2026-07-01T00:00:00Z.db")
+		if m == nil {
+			t.Fatal("expected pattern to match")
+		}
+		if got := m[re.SubexpIndex("ts")]; got != "2026-07-01T00:00:00Z" {
+			t.Fatalf("expected captured ts %q, got %q", "2026-07-01T00:00:00Z", got)
+		}
+	})
+
+	t.Run("gen placeholder matches and captures", func(t *testing.T) {
+		re, err := compileKeyPattern("backups/snapshot-{gen}.db")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		m := re.FindStringSubmatch("backups/snapshot-42.db")
+		if m == nil {
+			t.Fatal("expected pattern to match")
+		}
+		if got := m[re.SubexpIndex("gen")]; got != "42" {
+			t.Fatalf("expected captured gen %q, got %q", "42", got)
+		}
+	})
+
+	t.Run("non-matching key is rejected by the compiled pattern", func(t *testing.T) {
+		re, err := compileKeyPattern("backups/snapshot-{gen}.db")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if re.MatchString("backups/other-42.db") {
+			t.Fatal("expected pattern not to match")
+		}
+	})
+}
+
+func Test_ParseSnapshotKeys(t *testing.T) {
+	re, err := compileKeyPattern("snapshot-{gen}.db")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys := []string{"snapshot-1.db", "snapshot-2.db", "unrelated.txt"}
+	parsed, err := parseSnapshotKeys(re, keys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 parsed keys, got %d", len(parsed))
+	}
+	if parsed[0].gen != 1 || parsed[1].gen != 2 {
+		t.Fatalf("unexpected generations: %+v", parsed)
+	}
+}
+
+func Test_SelectByAsOf(t *testing.T) {
+	re, err := compileKeyPattern("snapshot-{ts}.db")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys := []string{
+		"snapshot-2026-01-01T00:00:00Z.db",
+		"snapshot-2026-02-01T00:00:00Z.db",
+		"snapshot-2026-03-01T00:00:00Z.db",
+	}
+	parsed, err := parseSnapshotKeys(re, keys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("picks newest at or before as_of", func(t *testing.T) {
+		asOf, err := time.Parse(time.RFC3339, "2026-02-15T00:00:00Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := selectByAsOf(parsed, asOf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "snapshot-2026-02-01T00:00:00Z.db" {
+			t.Fatalf("unexpected selection: %s", got)
+		}
+	})
+
+	t.Run("no snapshot before as_of is an error", func(t *testing.T) {
+		asOf, err := time.Parse(time.RFC3339, "2025-01-01T00:00:00Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := selectByAsOf(parsed, asOf); !errors.Is(err, ErrNoMatchingSnapshot) {
+			t.Fatalf("expected ErrNoMatchingSnapshot, got %v", err)
+		}
+	})
+}
+
+func Test_SelectByGeneration(t *testing.T) {
+	re, err := compileKeyPattern("snapshot-{gen}.db")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys := []string{"snapshot-1.db", "snapshot-5.db", "snapshot-10.db"}
+	parsed, err := parseSnapshotKeys(re, keys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("picks highest generation at or below max", func(t *testing.T) {
+		got, err := selectByGeneration(parsed, 9)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "snapshot-5.db" {
+			t.Fatalf("unexpected selection: %s", got)
+		}
+	})
+
+	t.Run("no snapshot at or below max is an error", func(t *testing.T) {
+		if _, err := selectByGeneration(parsed, 0); !errors.Is(err, ErrNoMatchingSnapshot) {
+			t.Fatalf("expected ErrNoMatchingSnapshot, got %v", err)
+		}
+	})
+}
+
+func Test_SelectPointInTime(t *testing.T) {
+	t.Run("selects by as_of", func(t *testing.T) {
+		sub := &fakeLister{prefix: "backups/", keys: []string{
+			"backups/snapshot-2026-01-01T00:00:00Z.db",
+			"backups/snapshot-2026-02-01T00:00:00Z.db",
+		}}
+		cfg := &Config{
+			Mode:       ModePointInTime,
+			KeyPattern: "backups/snapshot-{ts}.db",
+			AsOf:       "2026-01-15T00:00:00Z",
+		}
+		got, err := SelectPointInTime(context.Background(), cfg, sub)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "backups/snapshot-2026-01-01T00:00:00Z.db" {
+			t.Fatalf("unexpected selection: %s", got)
+		}
+	})
+
+	t.Run("selects by max_generation", func(t *testing.T) {
+		gen := int64(5)
+		sub := &fakeLister{prefix: "backups/", keys: []string{
+			"backups/snapshot-1.db",
+			"backups/snapshot-5.db",
+			"backups/snapshot-9.db",
+		}}
+		cfg := &Config{
+			Mode:          ModePointInTime,
+			KeyPattern:    "backups/snapshot-{gen}.db",
+			MaxGeneration: &gen,
+		}
+		got, err := SelectPointInTime(context.Background(), cfg, sub)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "backups/snapshot-5.db" {
+			t.Fatalf("unexpected selection: %s", got)
+		}
+	})
+
+	t.Run("source without List support is rejected", func(t *testing.T) {
+		cfg := &Config{
+			Mode:       ModePointInTime,
+			KeyPattern: "backups/snapshot-{gen}.db",
+		}
+		if _, err := SelectPointInTime(context.Background(), cfg, struct{}{}); err == nil {
+			t.Fatal("expected error for unsupported source")
+		}
+	})
+}
+
+func Test_ValidatePointInTime(t *testing.T) {
+	t.Run("single mode is always valid", func(t *testing.T) {
+		if err := validatePointInTime(&Config{Mode: ModeSingle}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("point_in_time requires exactly one of as_of or max_generation", func(t *testing.T) {
+		cfg := &Config{Mode: ModePointInTime, KeyPattern: "snapshot-{gen}.db"}
+		if err := validatePointInTime(cfg); !errors.Is(err, ErrInvalidPointInTimeConfig) {
+			t.Fatalf("expected ErrInvalidPointInTimeConfig, got %v", err)
+		}
+
+		gen := int64(1)
+		cfg.MaxGeneration = &gen
+		cfg.AsOf = "2026-01-01T00:00:00Z"
+		if err := validatePointInTime(cfg); !errors.Is(err, ErrInvalidPointInTimeConfig) {
+			t.Fatalf("expected ErrInvalidPointInTimeConfig, got %v", err)
+		}
+	})
+
+	t.Run("invalid as_of is rejected", func(t *testing.T) {
+		cfg := &Config{Mode: ModePointInTime, KeyPattern: "snapshot-{ts}.db", AsOf: "not-a-time"}
+		if err := validatePointInTime(cfg); !errors.Is(err, ErrInvalidPointInTimeConfig) {
+			t.Fatalf("expected ErrInvalidPointInTimeConfig, got %v", err)
+		}
+	})
+
+	t.Run("unknown mode is rejected", func(t *testing.T) {
+		cfg := &Config{Mode: "bogus"}
+		if err := validatePointInTime(cfg); !errors.Is(err, ErrInvalidPointInTimeConfig) {
+			t.Fatalf("expected ErrInvalidPointInTimeConfig, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package restore
+
+import (
+	"context"
+	"io"
+
+	"github.com/rqlite/rqlite/v8/aws"
+)
+
+// toS3Config returns the equivalent aws.S3Config for c, so
+// S3CompatibleConfig can delegate to aws.S3Config's already-tested
+// Download/DownloadChecksum/List implementations instead of duplicating
+// them.
+func (c *S3CompatibleConfig) toS3Config() *aws.S3Config {
+	return &aws.S3Config{
+		Endpoint:        c.Endpoint,
+		Region:          c.Region,
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+		Bucket:          c.Bucket,
+		Path:            c.Path,
+		ForcePathStyle:  c.PathStyle,
+		DisableSSL:      c.DisableSSL,
+	}
+}
+
+// Download fetches the configured object from an S3-compatible endpoint
+// such as MinIO, Ceph RGW, Backblaze B2, or Cloudflare R2, and returns a
+// reader over its raw bytes.
+func (c *S3CompatibleConfig) Download(ctx context.Context) (io.ReadCloser, error) {
+	return c.toS3Config().Download(ctx)
+}
+
+// DownloadChecksum fetches the sibling "<path>.sha256" object and
+// returns its contents as a trimmed string.
+func (c *S3CompatibleConfig) DownloadChecksum(ctx context.Context) (string, error) {
+	return c.toS3Config().DownloadChecksum(ctx)
+}
+
+// List returns the keys of every object in the bucket beginning with
+// prefix.
+func (c *S3CompatibleConfig) List(ctx context.Context, prefix string) ([]string, error) {
+	return c.toS3Config().List(ctx, prefix)
+}
+
+// Prefix returns c.Path, the prefix under which point_in_time mode lists
+// candidate snapshots.
+func (c *S3CompatibleConfig) Prefix() string {
+	return c.Path
+}
+
+// SetPath rewrites c.Path to key, the full object key a point_in_time
+// restore resolved from the configured prefix.
+func (c *S3CompatibleConfig) SetPath(key string) {
+	c.Path = key
+}
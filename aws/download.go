@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Download fetches the object described by c from S3 and returns a
+// reader over its raw bytes. It satisfies the restore.Downloader
+// interface.
+func (c *S3Config) Download(ctx context.Context) (io.ReadCloser, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := &s3.GetObjectInput{
+		Bucket: awssdk.String(c.Bucket),
+		Key:    awssdk.String(c.Path),
+	}
+	c.applySSECustomerKey(in)
+
+	out, err := client.GetObject(ctx, in)
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+	return out.Body, nil
+}
+
+// applySSECustomerKey sets the SSE-C request headers on in when the
+// config selects customer-provided-key encryption. An object encrypted
+// with SSE-C must have the same key supplied on every GetObject call, or
+// S3 will reject the request.
+func (c *S3Config) applySSECustomerKey(in *s3.GetObjectInput) {
+	if c.SSE != SSEC || c.SSECustomerKey == "" {
+		return
+	}
+	key, err := base64.StdEncoding.DecodeString(c.SSECustomerKey)
+	if err != nil {
+		return
+	}
+	sum := md5.Sum(key)
+	in.SSECustomerAlgorithm = awssdk.String("AES256")
+	in.SSECustomerKey = awssdk.String(c.SSECustomerKey)
+	in.SSECustomerKeyMD5 = awssdk.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// DownloadChecksum fetches the sibling "<path>.sha256" object and
+// returns its contents as a trimmed string. It satisfies the
+// restore.ChecksumDownloader interface.
+func (c *S3Config) DownloadChecksum(ctx context.Context) (string, error) {
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: awssdk.String(c.Bucket),
+		Key:    awssdk.String(c.Path + ".sha256"),
+	})
+	if err != nil {
+		return "", ClassifyError(err)
+	}
+	defer out.Body.Close()
+	b, err := io.ReadAll(out.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// List returns the keys of every object in the bucket beginning with
+// prefix. It satisfies the restore.Lister interface, used for
+// point_in_time restores.
+func (c *S3Config) List(ctx context.Context, prefix string) ([]string, error) {
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	p := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: awssdk.String(c.Bucket),
+		Prefix: awssdk.String(prefix),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, ClassifyError(err)
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, awssdk.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+// Prefix returns c.Path, the prefix under which point_in_time mode lists
+// candidate snapshots. It satisfies the restore.Prefixer interface.
+func (c *S3Config) Prefix() string {
+	return c.Path
+}
+
+// SetPath rewrites c.Path to key, the full object key a point_in_time
+// restore resolved from the configured prefix. It satisfies the
+// restore.PathSetter interface.
+func (c *S3Config) SetPath(key string) {
+	c.Path = key
+}
+
+func (c *S3Config) newClient(ctx context.Context) (*s3.Client, error) {
+	var opts []func(*config.LoadOptions) error
+	if c.Region != "" {
+		opts = append(opts, config.WithRegion(c.Region))
+	}
+	if c.AccessKeyID != "" || c.SecretAccessKey != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			c.AccessKeyID, c.SecretAccessKey, "")))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if c.Endpoint != "" {
+			o.BaseEndpoint = awssdk.String(c.Endpoint)
+		}
+		o.UsePathStyle = c.ForcePathStyle
+		o.EndpointOptions.DisableHTTPS = c.DisableSSL
+	}), nil
+}
@@ -0,0 +1,29 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+
+	smithy "github.com/aws/smithy-go"
+
+	"github.com/rqlite/rqlite/v8/auto"
+)
+
+// ClassifyError maps an S3 (or S3-compatible) API error to
+// auto.ErrSourceNotFound or auto.ErrSourceAccessDenied where applicable,
+// so a multi-source restore knows when it's safe to fall back to the
+// next configured source.
+func ClassifyError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.ErrorCode() {
+	case "NoSuchKey", "NotFound":
+		return fmt.Errorf("%w: %s", auto.ErrSourceNotFound, err)
+	case "AccessDenied", "Forbidden":
+		return fmt.Errorf("%w: %s", auto.ErrSourceAccessDenied, err)
+	default:
+		return err
+	}
+}
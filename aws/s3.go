@@ -0,0 +1,83 @@
+// Package aws contains configuration and client helpers for talking to
+// AWS S3, and S3-compatible object storage, from the auto-backup and
+// auto-restore subsystems.
+package aws
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// SSEType identifies how an S3 object is encrypted at rest.
+type SSEType string
+
+// Supported server-side encryption types.
+const (
+	SSENone   SSEType = ""
+	SSEAES256 SSEType = "AES256"
+	SSEKMS    SSEType = "aws:kms"
+	SSEC      SSEType = "C"
+)
+
+// ErrInvalidSSEConfig is returned when an S3Config's server-side
+// encryption fields are inconsistent, e.g. an SSE-C key supplied without
+// selecting SSEC, or an SSE-KMS key ID supplied for a non-KMS type.
+var ErrInvalidSSEConfig = errors.New("invalid sse configuration")
+
+// S3Config is the configuration for connecting to, and authenticating
+// with, an S3 bucket.
+type S3Config struct {
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	Path            string `json:"path,omitempty"`
+	ForcePathStyle  bool   `json:"force_path_style,omitempty"`
+	// DisableSSL disables TLS when talking to Endpoint, for S3-compatible
+	// endpoints that don't terminate HTTPS themselves.
+	DisableSSL bool `json:"disable_ssl,omitempty"`
+
+	// SSE selects the server-side encryption applied to the object:
+	// "AES256", "aws:kms", or "C" (customer-provided key).
+	SSE SSEType `json:"sse,omitempty"`
+	// SSEKMSKeyID is the KMS key ID of an object when SSE is "aws:kms".
+	// It is checked by Validate only; Download does not send it, as S3
+	// decrypts SSE-KMS objects on GetObject without it.
+	SSEKMSKeyID string `json:"sse_kms_key_id,omitempty"`
+	// SSECustomerKey is the base64-encoded, 256-bit customer-provided
+	// key to use when SSE is "C".
+	SSECustomerKey string `json:"sse_customer_key,omitempty"`
+}
+
+// Validate checks that the SSE-related fields of c are internally
+// consistent.
+func (c *S3Config) Validate() error {
+	switch c.SSE {
+	case SSENone, SSEAES256:
+		if c.SSEKMSKeyID != "" {
+			return fmt.Errorf("%w: sse_kms_key_id is only valid when sse is \"aws:kms\"", ErrInvalidSSEConfig)
+		}
+		if c.SSECustomerKey != "" {
+			return fmt.Errorf("%w: sse_customer_key is only valid when sse is \"C\"", ErrInvalidSSEConfig)
+		}
+	case SSEKMS:
+		if c.SSECustomerKey != "" {
+			return fmt.Errorf("%w: sse_customer_key is not valid when sse is \"aws:kms\"", ErrInvalidSSEConfig)
+		}
+	case SSEC:
+		if c.SSECustomerKey == "" {
+			return fmt.Errorf("%w: sse_customer_key is required when sse is \"C\"", ErrInvalidSSEConfig)
+		}
+		if c.SSEKMSKeyID != "" {
+			return fmt.Errorf("%w: sse_kms_key_id is not valid when sse is \"C\"", ErrInvalidSSEConfig)
+		}
+		if _, err := base64.StdEncoding.DecodeString(c.SSECustomerKey); err != nil {
+			return fmt.Errorf("%w: sse_customer_key is not valid base64: %v", ErrInvalidSSEConfig, err)
+		}
+	default:
+		return fmt.Errorf("%w: unknown sse type %q", ErrInvalidSSEConfig, c.SSE)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_S3Config_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     S3Config
+		wantErr error
+	}{
+		{
+			name:    "no sse",
+			cfg:     S3Config{},
+			wantErr: nil,
+		},
+		{
+			name:    "aes256",
+			cfg:     S3Config{SSE: SSEAES256},
+			wantErr: nil,
+		},
+		{
+			name:    "kms with key id",
+			cfg:     S3Config{SSE: SSEKMS, SSEKMSKeyID: "arn:aws:kms:us-west-2:111122223333:key/abc"},
+			wantErr: nil,
+		},
+		{
+			name:    "sse-c with customer key",
+			cfg:     S3Config{SSE: SSEC, SSECustomerKey: "c29tZWtleQ=="},
+			wantErr: nil,
+		},
+		{
+			name:    "sse-c missing customer key",
+			cfg:     S3Config{SSE: SSEC},
+			wantErr: ErrInvalidSSEConfig,
+		},
+		{
+			name:    "sse-c customer key not valid base64",
+			cfg:     S3Config{SSE: SSEC, SSECustomerKey: "not-valid-base64!!"},
+			wantErr: ErrInvalidSSEConfig,
+		},
+		{
+			name:    "kms key id without kms sse",
+			cfg:     S3Config{SSE: SSEAES256, SSEKMSKeyID: "arn:aws:kms:us-west-2:111122223333:key/abc"},
+			wantErr: ErrInvalidSSEConfig,
+		},
+		{
+			name:    "customer key without sse-c",
+			cfg:     S3Config{SSE: SSEKMS, SSEKMSKeyID: "arn:aws:kms:us-west-2:111122223333:key/abc", SSECustomerKey: "c29tZWtleQ=="},
+			wantErr: ErrInvalidSSEConfig,
+		},
+		{
+			name:    "unknown sse type",
+			cfg:     S3Config{SSE: "unknown"},
+			wantErr: ErrInvalidSSEConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}